@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// oscClipboardSeq is the OSC 52 "set clipboard" escape sequence. `%s` is
+// the base64-encoded payload.
+const oscClipboardSeq = "\x1b]52;c;%s\a"
+
+// Copy places text on the operator's clipboard via OSC 52, the same
+// mechanism termenv's Output.Copy uses, so it works even across SSH. It
+// silently does nothing when r's renderer has detected an Ascii profile,
+// since a terminal that dumb won't understand OSC 52 either.
+func (r *Renderer) Copy(text string) error {
+	if r.lg.ColorProfile() == termenv.Ascii {
+		return nil
+	}
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	seq := wrapForMultiplexer(fmt.Sprintf(oscClipboardSeq, encoded), r.term)
+
+	_, err := fmt.Fprint(r.out, seq)
+	return err
+}
+
+// wrapForMultiplexer wraps an escape sequence in the passthrough envelope
+// tmux/GNU screen require, since both otherwise swallow OSC 52 themselves
+// instead of forwarding it to the outer terminal. term is the TERM of the
+// stream actually being written to - for an SSH/Wish session that's the
+// client's own negotiated PTY term, not the orca-core host process's.
+func wrapForMultiplexer(seq, term string) string {
+	switch term := strings.ToLower(term); {
+	case strings.HasPrefix(term, "tmux"):
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(term, "screen"):
+		return "\x1bP" + seq + "\x1b\\"
+	default:
+		return seq
+	}
+}
@@ -2,66 +2,172 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
 	"github.com/muesli/termenv"
 )
 
-var (
-	// Muted violet headline
-	headerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#a387c4")). // soft lavender
+// syncWriter serializes writes from multiple goroutines onto a single
+// io.Writer, mirroring termenv's own locking around terminal output so
+// concurrent EmitWindow progress logs can't interleave mid-line.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Renderer bundles a single output stream's *lipgloss.Renderer with the
+// styles derived from its detected color profile and background. Each
+// stream (stdout, stderr, an SSH/Wish session) gets its own Renderer so
+// detection and color state never leak across goroutines or sessions.
+type Renderer struct {
+	lg   *lipgloss.Renderer
+	out  io.Writer
+	term string // the TERM of the stream this renderer was built for
+
+	headerStyle    lipgloss.Style
+	subHeaderStyle lipgloss.Style
+	successStyle   lipgloss.Style
+	warningStyle   lipgloss.Style
+	errorStyle     lipgloss.Style
+	infoStyle      lipgloss.Style
+	prefixStyle    lipgloss.Style
+}
+
+// NewRenderer constructs a Renderer for w, detecting its color profile and
+// dark/light background independently of any other output stream.
+func NewRenderer(w io.Writer, opts ...termenv.OutputOption) *Renderer {
+	sw := &syncWriter{w: w}
+	lg := lipgloss.NewRenderer(sw, opts...)
+	// termenv only recognises a terminal when handed the file itself, which
+	// sw hides, so detect from w directly and carry the result over to lg.
+	probe := termenv.NewOutput(w, opts...)
+	lg.SetColorProfile(probe.EnvColorProfile())
+	lg.SetHasDarkBackground(probe.HasDarkBackground())
+	applyColorProfile(lg)
+	r := newRendererFromLipgloss(lg)
+	r.out = sw
+	r.term = os.Getenv("TERM")
+	return r
+}
+
+// NewSessionRenderer constructs a Renderer scoped to a single Wish/SSH
+// session, detecting color profile and background from that session's PTY
+// rather than the host process's own stdout.
+func NewSessionRenderer(s ssh.Session) *Renderer {
+	r := NewRenderer(s, termenv.WithTTY(true))
+
+	pty, _, ok := s.Pty()
+	if !ok {
+		// No PTY was negotiated (e.g. a piped, non-interactive exec) -
+		// there's no terminal to detect a profile from, so don't guess.
+		r.lg.SetColorProfile(termenv.Ascii)
+		r.term = ""
+		return r
+	}
+	r.lg.SetColorProfile(profileForTerm(pty.Term))
+	// The session's own negotiated TERM, not the host process's - this is
+	// what Copy needs to decide whether the client's terminal is sitting
+	// behind a tmux/screen multiplexer.
+	r.term = pty.Term
+	return r
+}
+
+// profileForTerm maps a raw TERM string to a termenv.Profile using the same
+// tiers applyColorProfile applies to the host process's own TERM, so an SSH
+// session's negotiated terminal type drives its own detection instead of
+// the host's.
+func profileForTerm(term string) termenv.Profile {
+	term = strings.ToLower(term)
+
+	switch {
+	case term == "" || term == "dumb":
+		return termenv.Ascii
+	case strings.HasPrefix(term, "linux"), strings.HasPrefix(term, "console"),
+		strings.HasPrefix(term, "vt100"), strings.HasPrefix(term, "vt102"), strings.HasPrefix(term, "vt220"):
+		return termenv.ANSI
+	case strings.Contains(term, "256color"):
+		return termenv.ANSI256
+	case strings.Contains(term, "direct"), strings.Contains(term, "truecolor"):
+		return termenv.TrueColor
+	default:
+		return termenv.ANSI
+	}
+}
+
+func newRendererFromLipgloss(lg *lipgloss.Renderer) *Renderer {
+	return &Renderer{
+		lg: lg,
+
+		// Muted violet headline
+		headerStyle: lg.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#6b4f93", Dark: "#a387c4"}).
 			Bold(true).
-			Underline(true)
-
-	// Soft blue subheadings
-	subHeaderStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7aa2f7")). // muted periwinkle
-			Bold(true)
-
-	// Gentle green for success
-	successStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#9ece6a")) // desaturated lime
-
-	// Subtle gold for warnings
-	warningStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#e0af68")). // sandy gold
-			Bold(true)
-
-	// Muted red for errors
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#f7768e")). // rosy red
-			Bold(true)
-
-	// Cool teal for info messages
-	infoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7dcfff")) // soft cyan
-
-	// Gray prefix symbol
-	prefixStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#565f89")). // pastel gray-blue
-			SetString("→")
-)
+			Underline(true),
+
+		// Soft blue subheadings
+		subHeaderStyle: lg.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#3b5bdb", Dark: "#7aa2f7"}).
+			Bold(true),
+
+		// Gentle green for success
+		successStyle: lg.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#4b7f2f", Dark: "#9ece6a"}),
+
+		// Subtle gold for warnings
+		warningStyle: lg.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#8a5a00", Dark: "#e0af68"}).
+			Bold(true),
 
-func init() {
-	// Check for color support and set appropriate profile
-	setupColorProfile()
+		// Muted red for errors
+		errorStyle: lg.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#b3304c", Dark: "#f7768e"}).
+			Bold(true),
+
+		// Cool teal for info messages
+		infoStyle: lg.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#0f6fa3", Dark: "#7dcfff"}),
+
+		// Gray prefix symbol
+		prefixStyle: lg.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#6b7280", Dark: "#565f89"}).
+			SetString("→"),
+	}
 }
 
-// setupColorProfile detects terminal capabilities and sets appropriate color profile
-func setupColorProfile() {
+var (
+	// stdoutRenderer and stderrRenderer are the default renderers for the
+	// CLI's own output streams. Commands that render on behalf of another
+	// stream (an SSH session, a pipe) should construct their own Renderer
+	// with NewRenderer/NewSessionRenderer instead of reaching for these.
+	stdoutRenderer = NewRenderer(os.Stdout)
+	stderrRenderer = NewRenderer(os.Stderr)
+)
+
+// applyColorProfile detects terminal capabilities for a single renderer and
+// sets its color profile accordingly. Unlike the old global
+// lipgloss.SetColorProfile, this only affects lg's own output stream.
+func applyColorProfile(lg *lipgloss.Renderer) {
 	// Check for explicit no-color requests
 	if os.Getenv("NO_COLOR") != "" {
-		lipgloss.SetColorProfile(termenv.Ascii)
+		lg.SetColorProfile(termenv.Ascii)
 		return
 	}
 
 	// Check for dumb terminal
 	term := strings.ToLower(os.Getenv("TERM"))
 	if term == "dumb" || term == "" {
-		lipgloss.SetColorProfile(termenv.Ascii)
+		lg.SetColorProfile(termenv.Ascii)
 		return
 	}
 
@@ -69,25 +175,27 @@ func setupColorProfile() {
 	basicTerms := []string{"linux", "console", "vt100", "vt102", "vt220"}
 	for _, basicTerm := range basicTerms {
 		if strings.Contains(term, basicTerm) {
-			lipgloss.SetColorProfile(termenv.ANSI)
+			lg.SetColorProfile(termenv.ANSI)
 			return
 		}
 	}
 
 	// For CI environments, use basic colors
 	if os.Getenv("CI") != "" {
-		lipgloss.SetColorProfile(termenv.ANSI)
+		lg.SetColorProfile(termenv.ANSI)
 		return
 	}
 
-	// Default: let lipgloss auto-detect
-	// It will choose the best profile based on terminal capabilities
+	// Default: keep the profile and dark/light background NewRenderer
+	// detected from this renderer's own output, independently of every
+	// other renderer.
 }
 
-// safeRender safely renders text with styling, falling back to plain text on error
-func safeRender(style lipgloss.Style, text string) string {
+// safeRender safely renders text with styling, falling back to plain text
+// on error.
+func (r *Renderer) safeRender(style lipgloss.Style, text string) string {
 	defer func() {
-		if r := recover(); r != nil {
+		if rec := recover(); rec != nil {
 			// If styling fails, just return the plain text
 			fmt.Fprintf(os.Stderr, "Warning: styling failed, using plain text\n")
 		}
@@ -97,51 +205,64 @@ func safeRender(style lipgloss.Style, text string) string {
 	return style.Render(text)
 }
 
-// Maps container status to soft-styled output
-func statusColor(status string) lipgloss.Style {
+// StatusColor maps a container status to its soft-styled output, scoped to
+// r's renderer.
+func (r *Renderer) StatusColor(status string) lipgloss.Style {
 	switch status {
 	case "running":
-		return successStyle
+		return r.successStyle
 	case "stopped":
-		return warningStyle
+		return r.warningStyle
 	default:
-		return errorStyle
+		return r.errorStyle
 	}
 }
 
-// Helper functions for safe rendering of common styles
-func renderSuccess(text string) string {
-	return safeRender(successStyle, text)
+// Helper methods for safe rendering of common styles, scoped to r's
+// renderer.
+
+func (r *Renderer) RenderSuccess(text string) string {
+	return r.safeRender(r.successStyle, text)
 }
 
-func renderError(text string) string {
-	return safeRender(errorStyle, text)
+func (r *Renderer) RenderError(text string) string {
+	return r.safeRender(r.errorStyle, text)
 }
 
-func renderWarning(text string) string {
-	return safeRender(warningStyle, text)
+func (r *Renderer) RenderWarning(text string) string {
+	return r.safeRender(r.warningStyle, text)
 }
 
-func renderInfo(text string) string {
-	return safeRender(infoStyle, text)
+func (r *Renderer) RenderInfo(text string) string {
+	return r.safeRender(r.infoStyle, text)
 }
 
-func renderHeader(text string) string {
-	return safeRender(headerStyle, text)
+func (r *Renderer) RenderHeader(text string) string {
+	return r.safeRender(r.headerStyle, text)
 }
 
-func renderSubHeader(text string) string {
-	return safeRender(subHeaderStyle, text)
+func (r *Renderer) RenderSubHeader(text string) string {
+	return r.safeRender(r.subHeaderStyle, text)
 }
 
-// debugColorProfile prints current color profile information for debugging
+// renderSuccess etc. are thin wrappers over the default renderers, kept for
+// call sites that render directly to the CLI's own streams: renderError
+// renders for stderr, the others for stdout.
+func renderSuccess(text string) string   { return stdoutRenderer.RenderSuccess(text) }
+func renderError(text string) string     { return stderrRenderer.RenderError(text) }
+func renderWarning(text string) string   { return stdoutRenderer.RenderWarning(text) }
+func renderInfo(text string) string      { return stdoutRenderer.RenderInfo(text) }
+func renderHeader(text string) string    { return stdoutRenderer.RenderHeader(text) }
+func renderSubHeader(text string) string { return stdoutRenderer.RenderSubHeader(text) }
+
+// debugColorProfile prints current color profile information for debugging.
 func debugColorProfile() {
 	fmt.Printf("TERM: %s\n", os.Getenv("TERM"))
 	fmt.Printf("COLORTERM: %s\n", os.Getenv("COLORTERM"))
 	fmt.Printf("NO_COLOR: %s\n", os.Getenv("NO_COLOR"))
 	fmt.Printf("CI: %s\n", os.Getenv("CI"))
 
-	profile := lipgloss.ColorProfile()
+	profile := stdoutRenderer.lg.ColorProfile()
 	var profileName string
 	switch profile {
 	case termenv.Ascii:
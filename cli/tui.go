@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+
+	pb "github.com/predixus/orca/core/protobufs/go"
+)
+
+// processorRow is one row of the `orca ps` status table: a processor and
+// the most recent window dispatched to it.
+type processorRow struct {
+	id         string
+	inputs     string
+	lastWindow string
+	lastEmit   string
+	status     string
+}
+
+// psModel is the Bubble Tea model backing the live `orca ps` view. It
+// subscribes to WatchProcessors and updates its rows as events arrive.
+type psModel struct {
+	ctx      context.Context
+	renderer *Renderer
+	client   pb.OrcaCoreClient
+	stream   pb.OrcaCore_WatchProcessorsClient
+
+	width    int
+	rows     map[string]*processorRow
+	selected int
+	err      error
+	flash    string
+}
+
+func newPSModel(ctx context.Context, r *Renderer, client pb.OrcaCoreClient) *psModel {
+	return &psModel{
+		ctx:      ctx,
+		renderer: r,
+		client:   client,
+		rows:     make(map[string]*processorRow),
+	}
+}
+
+type processorEventMsg *pb.ProcessorEvent
+
+type watchErrMsg struct{ err error }
+
+func (m *psModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		stream, err := m.client.WatchProcessors(m.ctx, &pb.WatchProcessorsRequest{})
+		if err != nil {
+			return watchErrMsg{err}
+		}
+		m.stream = stream
+		return m.receiveCmd()()
+	}
+}
+
+// receiveCmd reads the next event off the already-open WatchProcessors
+// stream.
+func (m *psModel) receiveCmd() tea.Cmd {
+	return func() tea.Msg {
+		event, err := m.stream.Recv()
+		if err != nil {
+			return watchErrMsg{err}
+		}
+		return processorEventMsg(event)
+	}
+}
+
+func (m *psModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.rows)-1 {
+				m.selected++
+			}
+		case "y":
+			m.copySelected()
+		}
+		return m, nil
+	case processorEventMsg:
+		m.apply(msg)
+		return m, m.receiveCmd()
+	case watchErrMsg:
+		m.err = msg.err
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m *psModel) apply(event *pb.ProcessorEvent) {
+	row, ok := m.rows[event.GetProcessorId()]
+	if !ok {
+		row = &processorRow{id: event.GetProcessorId()}
+		m.rows[event.GetProcessorId()] = row
+	}
+
+	switch event.GetKind() {
+	case pb.ProcessorEvent_PROCESSOR_REGISTERED:
+		row.status = "registered"
+	case pb.ProcessorEvent_WINDOW_EMITTED:
+		row.lastWindow = event.GetWindowId()
+		row.lastEmit = time.Unix(0, event.GetTimestampUnixNano()).Format(time.Kitchen)
+		row.status = "dispatched"
+	case pb.ProcessorEvent_PROCESSOR_EVICTED:
+		row.status = "evicted"
+	}
+}
+
+// copySelected copies the currently-selected row's processor ID to the
+// operator's clipboard via OSC 52, so it works even when Orca is driving a
+// TUI over SSH/Wish.
+func (m *psModel) copySelected() {
+	rows := m.sortedRows()
+	if m.selected < 0 || m.selected >= len(rows) {
+		return
+	}
+	id := rows[m.selected].id
+	if err := m.renderer.Copy(id); err != nil {
+		m.flash = fmt.Sprintf("copy failed: %v", err)
+		return
+	}
+	m.flash = fmt.Sprintf("copied %q", id)
+}
+
+func (m *psModel) sortedRows() []*processorRow {
+	rows := make([]*processorRow, 0, len(m.rows))
+	for _, row := range m.rows {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].id < rows[j].id })
+	return rows
+}
+
+func (m *psModel) View() string {
+	if m.err != nil {
+		return m.renderer.RenderError(fmt.Sprintf("watch processors: %v", m.err)) + "\n"
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+
+	t := table.New().
+		Width(width).
+		Headers("PROCESSOR", "INPUTS", "LAST WINDOW", "LAST EMIT", "STATUS").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return m.renderer.headerStyle
+			case row == m.selected:
+				// Mark the row that up/down navigation has selected, so an
+				// operator can see what pressing y will actually copy.
+				return m.renderer.lg.NewStyle().Reverse(true)
+			default:
+				return m.renderer.lg.NewStyle()
+			}
+		})
+	for _, row := range m.sortedRows() {
+		t.Row(row.id, row.inputs, row.lastWindow, row.lastEmit, row.status)
+	}
+
+	help := "q: quit  ↑/↓: select  y: copy processor ID"
+	if m.flash != "" {
+		help = m.flash
+	}
+	return t.Render() + "\n" + m.renderer.RenderInfo(help) + "\n"
+}
+
+// RunPS renders the processor status view for an `orca ps`-style command
+// against r, the caller's renderer for the output stream in play (the CLI's
+// own stdoutRenderer for a local invocation, or a NewSessionRenderer for an
+// SSH/Wish session) rather than assuming stdout. The live Bubble Tea table
+// is drawn on the process's own stdout and reads keys from its stdin; only
+// the plain-text path writes to r's stream. When noTUI is true (e.g.
+// --no-tui was passed, or stdout is a dumb terminal/CI), it falls back to
+// the existing plain-text rendering instead of the live Bubble Tea table.
+// When copy is true in the --no-tui path, the last listed processor's ID
+// is copied to the clipboard, mirroring the TUI's y keybinding for the one
+// row a single-shot command can surface.
+func RunPS(ctx context.Context, r *Renderer, client pb.OrcaCoreClient, noTUI, copy bool) error {
+	if noTUI {
+		return renderPSPlain(ctx, r, client, copy)
+	}
+
+	p := tea.NewProgram(newPSModel(ctx, r, client), tea.WithContext(ctx))
+	_, err := p.Run()
+	return err
+}
+
+func renderPSPlain(ctx context.Context, r *Renderer, client pb.OrcaCoreClient, copy bool) error {
+	stats, err := client.Stats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		return err
+	}
+
+	var lastID string
+	for _, p := range stats.GetProcessors() {
+		status := "alive"
+		if !p.GetAlive() {
+			status = "evicted"
+		}
+		lastID = p.GetProcessorId()
+		fmt.Fprintln(r.out, r.RenderInfo(fmt.Sprintf(
+			"%s  queue=%d dispatched=%d dropped=%d results=%d status=%s",
+			p.GetProcessorId(), p.GetQueueDepth(), p.GetDispatched(), p.GetDropped(),
+			p.GetResultsReceived(), status,
+		)))
+	}
+
+	if copy && lastID != "" {
+		if err := r.Copy(lastID); err != nil {
+			fmt.Fprintln(r.out, r.RenderWarning(fmt.Sprintf("copy failed: %v", err)))
+		}
+	}
+	return nil
+}
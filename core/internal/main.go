@@ -2,37 +2,39 @@ package internal
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
+	"time"
 
 	"github.com/bufbuild/protovalidate-go"
 	dlyr "github.com/predixus/orca/core/internal/datalayers"
 	types "github.com/predixus/orca/core/internal/types"
 	pb "github.com/predixus/orca/core/protobufs/go"
-	"google.golang.org/grpc"
-	"google.golang.org/protobuf/proto"
 )
 
+const heartbeatSweepInterval = 10 * time.Second
+
 type (
 	OrcaCoreServer struct {
 		pb.UnimplementedOrcaCoreServer
-		client types.Datalayer
+		client    types.Datalayer
+		registry  *ProcessorRegistry
+		validator *protovalidate.Validator
 	}
 )
 
-var (
-	MAX_PROCESSORS = 20
-	processors     = make(
-		[]grpc.ServerStreamingServer[pb.ProcessingTask],
-		MAX_PROCESSORS,
-		MAX_PROCESSORS,
-	)
-)
-
-// NewServer produces a new ORCA gRPC server
+// NewServer produces a new ORCA gRPC server. By default it validates
+// incoming messages with a zero-configuration protovalidate.Validator;
+// pass WithValidator to pre-register message types or inject custom CEL
+// functions instead. Validation itself is applied by
+// UnaryValidationInterceptor/StreamValidationInterceptor, which callers
+// should wire into their grpc.NewServer alongside this constructor.
 func NewServer(
 	ctx context.Context,
 	platform dlyr.Platform,
 	connStr string,
+	opts ...ServerOption,
 ) (*OrcaCoreServer, error) {
 	client, err := dlyr.NewDatalayerClient(ctx, platform, connStr)
 	if err != nil {
@@ -50,53 +52,118 @@ func NewServer(
 	s := &OrcaCoreServer{
 		client: client,
 	}
-	return s, nil
-}
-
-// validate a protobuf via protovalidate
-func validate[T proto.Message](msg T) error {
-	v, err := protovalidate.New()
-	if err != nil {
-		return err
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
 	}
-
-	if err := v.Validate(msg); err != nil {
-		return err
+	if s.registry == nil {
+		s.registry = NewProcessorRegistry()
+	}
+	if s.validator == nil {
+		if err := WithValidator()(s); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	go s.registry.RunHeartbeatSweep(ctx, heartbeatSweepInterval)
+	return s, nil
 }
 
-// Register a processor with orca-core. Called when a processor startsup.
+// Register a processor with orca-core. Called when a processor starts up;
+// the call stays open for the processor's lifetime, receiving a
+// ProcessingTask down the stream for every window dispatched to it.
 func (o *OrcaCoreServer) RegisterProcessor(
-	ctx context.Context,
 	proc *pb.ProcessorRegistration,
-) (*pb.Status, error) {
-	err := validate(proc)
-	if err != nil {
-		return nil, err
-	}
+	stream pb.OrcaCore_RegisterProcessorServer,
+) error {
 	slog.Info("registering processor")
-	err = dlyr.RegisterProcessor(ctx, o.client, proc)
-	if err != nil {
-		return nil, err
+	if err := dlyr.RegisterProcessor(stream.Context(), o.client, proc); err != nil {
+		return err
 	}
 	slog.Debug("registered processor", "processor", proc)
-	return &pb.Status{
-		Received: true,
-		Message:  "Successfully registered processor",
-	}, nil
+	return o.registry.Register(proc, stream)
 }
 
 func (o *OrcaCoreServer) EmitWindow(
 	ctx context.Context,
 	window *pb.Window,
 ) (*pb.WindowEmitStatus, error) {
-	err := validate(window)
-	if err != nil {
-		return nil, err
-	}
 	slog.Info("emitting window", "window", window)
 	windowEmitStatus, err := o.client.EmitWindow(ctx, window)
-	return &windowEmitStatus, err
+	if err != nil {
+		return &windowEmitStatus, err
+	}
+	windowEmitStatus.DispatchedTo = o.registry.Dispatch(window)
+	return &windowEmitStatus, nil
+}
+
+// ProcessingResults receives the stream of results a processor reports back
+// as it finishes the ProcessingTasks dispatched to it.
+func (o *OrcaCoreServer) ProcessingResults(
+	stream pb.OrcaCore_ProcessingResultsServer,
+) error {
+	var processorID string
+	var received int
+	for {
+		result, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		processorID = result.GetProcessorId()
+		o.registry.RecordResult(processorID)
+		received++
+		if result.GetError() != "" {
+			slog.Warn("processor reported task error",
+				"processor_id", processorID, "task_id", result.GetTaskId(), "error", result.GetError())
+		}
+	}
+	slog.Debug("recorded processing results", "processor_id", processorID, "count", received)
+	return stream.SendAndClose(&pb.Status{
+		Received: true,
+		Message:  "recorded results from processor",
+	})
+}
+
+// Heartbeat is called periodically by a registered processor to prove
+// liveness.
+func (o *OrcaCoreServer) Heartbeat(
+	ctx context.Context,
+	req *pb.HeartbeatRequest,
+) (*pb.Status, error) {
+	o.registry.RecordHeartbeat(req.GetProcessorId())
+	return &pb.Status{Received: true}, nil
+}
+
+// Stats reports registry and dispatch counters for observability.
+func (o *OrcaCoreServer) Stats(
+	ctx context.Context,
+	req *pb.StatsRequest,
+) (*pb.StatsResponse, error) {
+	return o.registry.Stats(), nil
+}
+
+// WatchProcessors streams registry events for live status views such as
+// `orca ps`.
+func (o *OrcaCoreServer) WatchProcessors(
+	req *pb.WatchProcessorsRequest,
+	stream pb.OrcaCore_WatchProcessorsServer,
+) error {
+	events, cancel := o.registry.Subscribe()
+	defer cancel()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
 }
@@ -0,0 +1,382 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/predixus/orca/core/protobufs/go"
+)
+
+// BackpressureMode controls what a ProcessorRegistry does when a
+// processor's task queue is full.
+type BackpressureMode int
+
+const (
+	// DropOldest evicts the oldest queued task to make room for the new
+	// one. Appropriate for processors where only the freshest window
+	// matters.
+	DropOldest BackpressureMode = iota
+	// BlockWithTimeout waits up to the registry's configured timeout for
+	// room in the queue before giving up on that processor.
+	BlockWithTimeout
+)
+
+const (
+	defaultQueueSize        = 64
+	defaultBlockTimeout     = 2 * time.Second
+	defaultHeartbeatTimeout = 30 * time.Second
+)
+
+// registeredProcessor is the live, in-memory handle for a processor that is
+// currently connected via the RegisterProcessor stream.
+type registeredProcessor struct {
+	id             string
+	declaredInputs []string
+	stream         pb.OrcaCore_RegisterProcessorServer
+
+	queue chan *pb.ProcessingTask
+
+	lastHeartbeat atomic.Int64 // unix nanos
+
+	dispatched      atomic.Int64
+	dropped         atomic.Int64
+	resultsReceived atomic.Int64
+}
+
+func (p *registeredProcessor) touch() {
+	p.lastHeartbeat.Store(time.Now().UnixNano())
+}
+
+func (p *registeredProcessor) stale(timeout time.Duration) bool {
+	last := time.Unix(0, p.lastHeartbeat.Load())
+	return time.Since(last) > timeout
+}
+
+func (p *registeredProcessor) accepts(kind string) bool {
+	for _, in := range p.declaredInputs {
+		if in == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessorRegistry tracks every processor currently registered against
+// orca-core, dispatches ProcessingTasks to the ones whose declared inputs
+// match an emitted window, and evicts processors whose heartbeat goes
+// stale.
+type ProcessorRegistry struct {
+	mu         sync.RWMutex
+	processors map[string]*registeredProcessor
+
+	queueSize        int
+	backpressure     BackpressureMode
+	blockTimeout     time.Duration
+	heartbeatTimeout time.Duration
+
+	subMu     sync.Mutex
+	subs      map[int]chan *pb.ProcessorEvent
+	nextSubID int
+}
+
+// RegistryOption configures a ProcessorRegistry.
+type RegistryOption func(*ProcessorRegistry)
+
+// WithQueueSize sets the bounded per-processor task queue size.
+func WithQueueSize(n int) RegistryOption {
+	return func(r *ProcessorRegistry) { r.queueSize = n }
+}
+
+// WithBackpressure sets the policy applied when a processor's queue is full.
+// blockTimeout is only used when mode is BlockWithTimeout.
+func WithBackpressure(mode BackpressureMode, blockTimeout time.Duration) RegistryOption {
+	return func(r *ProcessorRegistry) {
+		r.backpressure = mode
+		r.blockTimeout = blockTimeout
+	}
+}
+
+// WithHeartbeatTimeout sets how long a processor may go without a heartbeat
+// before EvictStale removes it.
+func WithHeartbeatTimeout(d time.Duration) RegistryOption {
+	return func(r *ProcessorRegistry) { r.heartbeatTimeout = d }
+}
+
+// WithRegistry replaces the server's default ProcessorRegistry with one
+// built from opts (e.g. WithQueueSize, WithBackpressure,
+// WithHeartbeatTimeout), instead of the zero-configuration registry
+// NewServer constructs otherwise. This is the only way to reach those
+// options through the public NewServer API.
+func WithRegistry(opts ...RegistryOption) ServerOption {
+	return func(s *OrcaCoreServer) error {
+		s.registry = NewProcessorRegistry(opts...)
+		return nil
+	}
+}
+
+// NewProcessorRegistry constructs an empty ProcessorRegistry.
+func NewProcessorRegistry(opts ...RegistryOption) *ProcessorRegistry {
+	r := &ProcessorRegistry{
+		processors:       make(map[string]*registeredProcessor),
+		queueSize:        defaultQueueSize,
+		backpressure:     DropOldest,
+		blockTimeout:     defaultBlockTimeout,
+		heartbeatTimeout: defaultHeartbeatTimeout,
+		subs:             make(map[int]chan *pb.ProcessorEvent),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register tracks proc's streaming handle, sends it every task queued for
+// it, and blocks until the stream's context is done or a send fails; it
+// deregisters the processor itself when it returns. Callers should run
+// Register in the RegisterProcessor RPC handler. A Deregister call made
+// while Register is blocked (e.g. by a heartbeat sweep) stops dispatch to
+// the processor but does not end the Register call.
+func (r *ProcessorRegistry) Register(
+	proc *pb.ProcessorRegistration,
+	stream pb.OrcaCore_RegisterProcessorServer,
+) error {
+	p := &registeredProcessor{
+		id:             proc.GetProcessorId(),
+		declaredInputs: proc.GetDeclaredInputs(),
+		stream:         stream,
+		queue:          make(chan *pb.ProcessingTask, r.queueSize),
+	}
+	p.touch()
+
+	r.mu.Lock()
+	r.processors[p.id] = p
+	r.mu.Unlock()
+
+	slog.Info("processor registered", "processor_id", p.id, "declared_inputs", p.declaredInputs)
+	r.publish(&pb.ProcessorEvent{
+		Kind:              pb.ProcessorEvent_PROCESSOR_REGISTERED,
+		ProcessorId:       p.id,
+		TimestampUnixNano: time.Now().UnixNano(),
+	})
+	defer r.Deregister(p.id)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case task := <-p.queue:
+			if err := stream.Send(task); err != nil {
+				return fmt.Errorf("sending task to processor %q: %w", p.id, err)
+			}
+		}
+	}
+}
+
+// Deregister removes a processor from the registry, e.g. once its stream
+// has ended or a heartbeat sweep has found it stale.
+func (r *ProcessorRegistry) Deregister(processorID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.processors[processorID]; ok {
+		delete(r.processors, processorID)
+		slog.Info("processor deregistered", "processor_id", processorID)
+		r.publish(&pb.ProcessorEvent{
+			Kind:              pb.ProcessorEvent_PROCESSOR_EVICTED,
+			ProcessorId:       processorID,
+			TimestampUnixNano: time.Now().UnixNano(),
+		})
+	}
+}
+
+// Dispatch fans window out, as a ProcessingTask, to every processor whose
+// declared inputs match window's kind. It returns the IDs of the
+// processors the task was handed to (successfully queued, not necessarily
+// yet sent).
+func (r *ProcessorRegistry) Dispatch(window *pb.Window) []string {
+	task := &pb.ProcessingTask{
+		TaskId:   window.GetWindowId(),
+		WindowId: window.GetWindowId(),
+		Payload:  window.GetPayload(),
+	}
+
+	r.mu.RLock()
+	matched := make([]*registeredProcessor, 0, len(r.processors))
+	for _, p := range r.processors {
+		if p.accepts(window.GetKind()) {
+			matched = append(matched, p)
+		}
+	}
+	r.mu.RUnlock()
+
+	dispatchedTo := make([]string, 0, len(matched))
+	for _, p := range matched {
+		if r.enqueue(p, task) {
+			p.dispatched.Add(1)
+			dispatchedTo = append(dispatchedTo, p.id)
+			r.publish(&pb.ProcessorEvent{
+				Kind:              pb.ProcessorEvent_WINDOW_EMITTED,
+				ProcessorId:       p.id,
+				WindowId:          window.GetWindowId(),
+				TimestampUnixNano: time.Now().UnixNano(),
+			})
+		}
+	}
+	return dispatchedTo
+}
+
+// Subscribe registers a new watcher for registry events, returning a
+// channel of events and a cancel func that unsubscribes it. Callers should
+// always defer cancel() once they stop reading from the channel.
+func (r *ProcessorRegistry) Subscribe() (<-chan *pb.ProcessorEvent, func()) {
+	ch := make(chan *pb.ProcessorEvent, 16)
+
+	r.subMu.Lock()
+	id := r.nextSubID
+	r.nextSubID++
+	r.subs[id] = ch
+	r.subMu.Unlock()
+
+	cancel := func() {
+		r.subMu.Lock()
+		defer r.subMu.Unlock()
+		if _, ok := r.subs[id]; ok {
+			delete(r.subs, id)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+// publish fans event out to every subscriber. A subscriber that isn't
+// keeping up has the event dropped rather than blocking dispatch.
+func (r *ProcessorRegistry) publish(event *pb.ProcessorEvent) {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	for _, ch := range r.subs {
+		select {
+		case ch <- event:
+		default:
+			slog.Warn("dropping processor event for slow watcher")
+		}
+	}
+}
+
+// enqueue places task on p's queue according to the registry's configured
+// backpressure policy, returning whether the task was ultimately queued.
+func (r *ProcessorRegistry) enqueue(p *registeredProcessor, task *pb.ProcessingTask) bool {
+	select {
+	case p.queue <- task:
+		return true
+	default:
+	}
+
+	switch r.backpressure {
+	case DropOldest:
+		select {
+		case <-p.queue:
+			p.dropped.Add(1)
+		default:
+		}
+		select {
+		case p.queue <- task:
+			return true
+		default:
+			p.dropped.Add(1)
+			return false
+		}
+	case BlockWithTimeout:
+		timer := time.NewTimer(r.blockTimeout)
+		defer timer.Stop()
+		select {
+		case p.queue <- task:
+			return true
+		case <-timer.C:
+			p.dropped.Add(1)
+			slog.Warn("dropping task after backpressure timeout", "processor_id", p.id)
+			return false
+		}
+	default:
+		p.dropped.Add(1)
+		return false
+	}
+}
+
+// RecordHeartbeat marks processorID as alive. It is a no-op if the
+// processor is not currently registered.
+func (r *ProcessorRegistry) RecordHeartbeat(processorID string) {
+	r.mu.RLock()
+	p, ok := r.processors[processorID]
+	r.mu.RUnlock()
+	if ok {
+		p.touch()
+	}
+}
+
+// RecordResult notes that processorID has reported back a ProcessingResult.
+func (r *ProcessorRegistry) RecordResult(processorID string) {
+	r.mu.RLock()
+	p, ok := r.processors[processorID]
+	r.mu.RUnlock()
+	if ok {
+		p.resultsReceived.Add(1)
+	}
+}
+
+// EvictStale deregisters every processor whose heartbeat is older than the
+// registry's configured heartbeat timeout.
+func (r *ProcessorRegistry) EvictStale() {
+	r.mu.RLock()
+	stale := make([]string, 0)
+	for id, p := range r.processors {
+		if p.stale(r.heartbeatTimeout) {
+			stale = append(stale, id)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, id := range stale {
+		slog.Warn("evicting processor with stale heartbeat", "processor_id", id)
+		r.Deregister(id)
+	}
+}
+
+// RunHeartbeatSweep evicts stale processors every interval until ctx is
+// done. Callers should run this in its own goroutine from NewServer.
+func (r *ProcessorRegistry) RunHeartbeatSweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.EvictStale()
+		}
+	}
+}
+
+// Stats reports per-processor queue depth and dispatch counters.
+func (r *ProcessorRegistry) Stats() *pb.StatsResponse {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	resp := &pb.StatsResponse{
+		Processors: make([]*pb.ProcessorStats, 0, len(r.processors)),
+	}
+	for _, p := range r.processors {
+		resp.Processors = append(resp.Processors, &pb.ProcessorStats{
+			ProcessorId:     p.id,
+			QueueDepth:      int64(len(p.queue)),
+			Dispatched:      p.dispatched.Load(),
+			Dropped:         p.dropped.Load(),
+			ResultsReceived: p.resultsReceived.Load(),
+			Alive:           !p.stale(r.heartbeatTimeout),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/predixus/orca/core/protobufs/go"
+)
+
+func newTestProcessor(queueSize int) *registeredProcessor {
+	return &registeredProcessor{
+		id:             "proc-1",
+		declaredInputs: []string{"temperature"},
+		queue:          make(chan *pb.ProcessingTask, queueSize),
+	}
+}
+
+func TestEnqueueDropOldest(t *testing.T) {
+	r := NewProcessorRegistry(WithQueueSize(2), WithBackpressure(DropOldest, time.Second))
+	p := newTestProcessor(2)
+
+	first := &pb.ProcessingTask{TaskId: "1"}
+	second := &pb.ProcessingTask{TaskId: "2"}
+	third := &pb.ProcessingTask{TaskId: "3"}
+
+	if ok := r.enqueue(p, first); !ok {
+		t.Fatalf("expected first task to be queued")
+	}
+	if ok := r.enqueue(p, second); !ok {
+		t.Fatalf("expected second task to be queued")
+	}
+	if ok := r.enqueue(p, third); !ok {
+		t.Fatalf("expected third task to be queued after dropping the oldest")
+	}
+
+	if p.dropped.Load() != 1 {
+		t.Fatalf("expected 1 dropped task, got %d", p.dropped.Load())
+	}
+
+	got := []string{(<-p.queue).GetTaskId(), (<-p.queue).GetTaskId()}
+	want := []string{"2", "3"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("queue contents = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEnqueueBlockWithTimeout(t *testing.T) {
+	r := NewProcessorRegistry(WithBackpressure(BlockWithTimeout, 20*time.Millisecond))
+	p := newTestProcessor(1)
+
+	if ok := r.enqueue(p, &pb.ProcessingTask{TaskId: "1"}); !ok {
+		t.Fatalf("expected first task to be queued")
+	}
+
+	start := time.Now()
+	ok := r.enqueue(p, &pb.ProcessingTask{TaskId: "2"})
+	elapsed := time.Since(start)
+
+	if ok {
+		t.Fatalf("expected second task to be dropped once the queue stayed full past the timeout")
+	}
+	if elapsed < 20*time.Millisecond {
+		t.Fatalf("enqueue returned after %s, expected it to wait out the configured timeout", elapsed)
+	}
+	if p.dropped.Load() != 1 {
+		t.Fatalf("expected 1 dropped task, got %d", p.dropped.Load())
+	}
+}
+
+func TestEnqueueBlockWithTimeoutSucceedsOnceDrained(t *testing.T) {
+	r := NewProcessorRegistry(WithBackpressure(BlockWithTimeout, time.Second))
+	p := newTestProcessor(1)
+
+	if ok := r.enqueue(p, &pb.ProcessingTask{TaskId: "1"}); !ok {
+		t.Fatalf("expected first task to be queued")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-p.queue
+	}()
+
+	if ok := r.enqueue(p, &pb.ProcessingTask{TaskId: "2"}); !ok {
+		t.Fatalf("expected second task to be queued once room freed up within the timeout")
+	}
+}
+
+func TestEvictStaleRemovesOnlyStaleProcessors(t *testing.T) {
+	r := NewProcessorRegistry(WithHeartbeatTimeout(10 * time.Millisecond))
+
+	fresh := newTestProcessor(1)
+	fresh.id = "fresh"
+	fresh.touch()
+
+	stale := newTestProcessor(1)
+	stale.id = "stale"
+	stale.lastHeartbeat.Store(time.Now().Add(-time.Hour).UnixNano())
+
+	r.mu.Lock()
+	r.processors[fresh.id] = fresh
+	r.processors[stale.id] = stale
+	r.mu.Unlock()
+
+	r.EvictStale()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.processors["stale"]; ok {
+		t.Fatalf("expected stale processor to be evicted")
+	}
+	if _, ok := r.processors["fresh"]; !ok {
+		t.Fatalf("expected fresh processor to remain registered")
+	}
+}
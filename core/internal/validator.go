@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// ServerOption configures an OrcaCoreServer at construction time.
+type ServerOption func(*OrcaCoreServer) error
+
+// WithValidator replaces the server's default protovalidate.Validator with
+// one built from opts, instead of the zero-configuration validator NewServer
+// constructs otherwise. Use protovalidate.WithMessages to pre-register
+// message types so their CEL constraints are compiled once up front rather
+// than lazily on first use, and protovalidate.WithCELEnvironmentOptions to
+// inject custom CEL functions such as HasCapabilityFunc for
+// ProcessorRegistration.
+func WithValidator(opts ...protovalidate.ValidatorOption) ServerOption {
+	return func(s *OrcaCoreServer) error {
+		v, err := protovalidate.New(opts...)
+		if err != nil {
+			return err
+		}
+		s.validator = v
+		return nil
+	}
+}
+
+// HasCapabilityFunc registers a has_capability(declared_inputs, capability)
+// CEL predicate that checks a capability string against a processor's
+// declared inputs, for use in CEL constraints on ProcessorRegistration.
+// ProcessorRegistration declares no such constraint in orca.proto.
+func HasCapabilityFunc() cel.EnvOption {
+	return cel.Function("has_capability",
+		cel.Overload("has_capability_list_string",
+			[]*cel.Type{cel.ListType(cel.StringType), cel.StringType},
+			cel.BoolType,
+			cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+				list, ok := lhs.(traits.Lister)
+				if !ok {
+					return types.NewErr("has_capability: expected list, got %T", lhs)
+				}
+				for it := list.Iterator(); it.HasNext() == types.True; {
+					if it.Next().Equal(rhs) == types.True {
+						return types.True
+					}
+				}
+				return types.False
+			}),
+		),
+	)
+}
+
+// validatingStream wraps a grpc.ServerStream so every message it receives
+// is run through the server's validator before the handler sees it.
+type validatingStream struct {
+	grpc.ServerStream
+	validator *protovalidate.Validator
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		return s.validator.Validate(msg)
+	}
+	return nil
+}
+
+// UnaryValidationInterceptor validates every unary request against o's
+// validator before the handler runs, so RPC handlers need not validate
+// their requests themselves.
+func UnaryValidationInterceptor(o *OrcaCoreServer) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := o.validator.Validate(msg); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidationInterceptor is the streaming-RPC counterpart of
+// UnaryValidationInterceptor: it validates every message received over a
+// client- or bidi-streaming call, and the single request message of a
+// server-streaming call, against o's validator.
+func StreamValidationInterceptor(o *OrcaCoreServer) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &validatingStream{ServerStream: ss, validator: o.validator})
+	}
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func hasCapabilityTestEnv(t *testing.T) *cel.Env {
+	t.Helper()
+	env, err := cel.NewEnv(
+		HasCapabilityFunc(),
+		cel.Variable("declared_inputs", cel.ListType(cel.StringType)),
+		cel.Variable("capability", cel.StringType),
+	)
+	if err != nil {
+		t.Fatalf("building CEL env: %v", err)
+	}
+	return env
+}
+
+func evalHasCapability(t *testing.T, declaredInputs []string, capability string) bool {
+	t.Helper()
+	env := hasCapabilityTestEnv(t)
+
+	ast, iss := env.Compile(`has_capability(declared_inputs, capability)`)
+	if iss.Err() != nil {
+		t.Fatalf("compiling expression: %v", iss.Err())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("building program: %v", err)
+	}
+
+	out, _, err := program.Eval(map[string]any{
+		"declared_inputs": declaredInputs,
+		"capability":      capability,
+	})
+	if err != nil {
+		t.Fatalf("evaluating program: %v", err)
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		t.Fatalf("expected bool result, got %T", out.Value())
+	}
+	return result
+}
+
+func TestHasCapabilityMatch(t *testing.T) {
+	if !evalHasCapability(t, []string{"temperature", "humidity"}, "humidity") {
+		t.Fatalf("expected has_capability to find a declared input matching the requested capability")
+	}
+}
+
+func TestHasCapabilityNoMatch(t *testing.T) {
+	if evalHasCapability(t, []string{"temperature"}, "humidity") {
+		t.Fatalf("expected has_capability to report false when no declared input matches")
+	}
+}
+
+func TestHasCapabilityEmptyList(t *testing.T) {
+	if evalHasCapability(t, []string{}, "humidity") {
+		t.Fatalf("expected has_capability to report false for an empty declared_inputs list")
+	}
+}